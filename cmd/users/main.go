@@ -35,6 +35,7 @@ import (
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	"github.com/absmach/magistrala/pkg/groups"
 	"github.com/absmach/magistrala/pkg/oauth2"
+	githuboauth "github.com/absmach/magistrala/pkg/oauth2/github"
 	kratosoauth "github.com/absmach/magistrala/pkg/oauth2/kratos"
 	"github.com/absmach/magistrala/pkg/uuid"
 	"github.com/absmach/magistrala/users"
@@ -54,13 +55,14 @@ import (
 )
 
 const (
-	svcName         = "users"
-	envPrefixDB     = "MG_USERS_DB_"
-	envPrefixHTTP   = "MG_USERS_HTTP_"
-	envPrefixAuth   = "MG_AUTH_GRPC_"
-	envPrefixKratos = "MG_KRATOS_"
-	defDB           = "users"
-	defSvcHTTPPort  = "9002"
+	svcName              = "users"
+	envPrefixDB          = "MG_USERS_DB_"
+	envPrefixHTTP        = "MG_USERS_HTTP_"
+	envPrefixAuth        = "MG_AUTH_GRPC_"
+	envPrefixOAuthKratos = "MG_OAUTH_KRATOS_"
+	envPrefixOAuthGitHub = "MG_OAUTH_GITHUB_"
+	defDB                = "users"
+	defSvcHTTPPort       = "9002"
 
 	defKratosRetryCount   = 10
 	defKratosRetryWaitMax = 1 * time.Minute
@@ -183,16 +185,26 @@ func main() {
 		return
 	}
 
-	oauthConfig := oauth2.Config{}
-	if err := env.ParseWithOptions(&oauthConfig, env.Options{Prefix: envPrefixKratos}); err != nil {
-		logger.Error(fmt.Sprintf("failed to load %s Kratos configuration : %s", svcName, err.Error()))
+	oauthKratosConfig := oauth2.Config{}
+	if err := env.ParseWithOptions(&oauthKratosConfig, env.Options{Prefix: envPrefixOAuthKratos}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s Kratos OAuth configuration : %s", svcName, err.Error()))
 		exitCode = 1
 		return
 	}
-	oauthProvider := kratosoauth.NewProvider(oauthConfig, cfg.KratosURL, cfg.OAuthUIRedirectURL, cfg.OAuthUIErrorURL, cfg.KratosAPIKey)
+	kratosProvider := kratosoauth.NewProvider(oauthKratosConfig, cfg.KratosURL, cfg.OAuthUIRedirectURL, cfg.OAuthUIErrorURL, cfg.KratosAPIKey)
+
+	oauthGitHubConfig := oauth2.Config{}
+	if err := env.ParseWithOptions(&oauthGitHubConfig, env.Options{Prefix: envPrefixOAuthGitHub}); err != nil {
+		logger.Error(fmt.Sprintf("failed to load %s GitHub OAuth configuration : %s", svcName, err.Error()))
+		exitCode = 1
+		return
+	}
+	githubProvider := githuboauth.NewProvider(oauthGitHubConfig, cfg.OAuthUIRedirectURL, cfg.OAuthUIErrorURL)
+
+	oauthProviders := oauth2.NewRegistry(kratosProvider, githubProvider)
 
 	mux := chi.NewRouter()
-	httpSrv := httpserver.New(ctx, cancel, svcName, httpServerConfig, capi.MakeHandler(csvc, gsvc, mux, logger, cfg.InstanceID, cfg.PassRegex, oauthProvider), logger)
+	httpSrv := httpserver.New(ctx, cancel, svcName, httpServerConfig, capi.MakeHandler(csvc, gsvc, mux, logger, cfg.InstanceID, cfg.PassRegex, oauthProviders), logger)
 
 	if cfg.SendTelemetry {
 		chc := chclient.New(svcName, magistrala.Version, logger, cancel)
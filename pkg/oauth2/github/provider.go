@@ -0,0 +1,237 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	mfclients "github.com/absmach/magistrala/pkg/clients"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+	"golang.org/x/oauth2"
+	githuboauth "golang.org/x/oauth2/github"
+)
+
+const (
+	providerName       = "github"
+	defStateTTL        = 5 * time.Minute
+	userAPIEndpoint    = "https://api.github.com/user"
+	userEmailsEndpoint = "https://api.github.com/user/emails"
+)
+
+var scopes = []string{
+	"read:user",
+	"user:email",
+}
+
+var _ mgoauth2.Provider = (*config)(nil)
+
+type config struct {
+	config        *oauth2.Config
+	states        mgoauth2.StateStore
+	uiRedirectURL string
+	errorURL      string
+}
+
+// NewProvider returns a new GitHub OAuth2 provider.
+func NewProvider(cfg mgoauth2.Config, uiRedirectURL, errorURL string) mgoauth2.Provider {
+	return &config{
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     githuboauth.Endpoint,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       scopes,
+		},
+		states:        mgoauth2.NewMemStateStore(defStateTTL),
+		uiRedirectURL: uiRedirectURL,
+		errorURL:      errorURL,
+	}
+}
+
+func (cfg *config) Name() string {
+	return providerName
+}
+
+// AuthCodeURL returns the GitHub authorization URL along with the opaque
+// state token it carries, so UserDetails can later recover which flow
+// (sign-in or sign-up) the callback belongs to. GitHub's OAuth Apps do not
+// support PKCE, so no code challenge is attached.
+func (cfg *config) AuthCodeURL(flow mgoauth2.Flow) (string, string, error) {
+	state, err := mgoauth2.GenerateState()
+	if err != nil {
+		return "", "", err
+	}
+	cfg.states.Put(state, mgoauth2.StateEntry{Flow: flow})
+
+	return cfg.config.AuthCodeURL(state), state, nil
+}
+
+func (cfg *config) RedirectURL() string {
+	return cfg.uiRedirectURL
+}
+
+func (cfg *config) ErrorURL() string {
+	return cfg.errorURL
+}
+
+func (cfg *config) IsEnabled() bool {
+	return cfg.config.ClientID != "" && cfg.config.ClientSecret != ""
+}
+
+func (cfg *config) UserDetails(ctx context.Context, state, code string) (mfclients.Client, oauth2.Token, mgoauth2.Flow, error) {
+	entry, ok := cfg.states.Take(state)
+	if !ok {
+		return mfclients.Client{}, oauth2.Token{}, "", svcerr.ErrAuthentication
+	}
+
+	token, err := cfg.config.Exchange(ctx, code)
+	if err != nil {
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
+	}
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := cfg.get(ctx, userAPIEndpoint, token.AccessToken, &user); err != nil {
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
+	}
+	if user.ID == 0 || user.Login == "" {
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, svcerr.ErrAuthentication
+	}
+
+	email := user.Email
+	if email == "" {
+		email, err = cfg.primaryEmail(ctx, token.AccessToken)
+		if err != nil {
+			return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
+		}
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+
+	client := mfclients.Client{
+		ID:   fmt.Sprintf("%d", user.ID),
+		Name: name,
+		Credentials: mfclients.Credentials{
+			Identity: email,
+		},
+		Metadata: map[string]interface{}{
+			"oauth_provider": providerName,
+		},
+		Status: mfclients.EnabledStatus,
+	}
+
+	return client, *token, entry.Flow, nil
+}
+
+// primaryEmail fetches the user's verified primary e-mail address. GitHub
+// only returns the e-mail on the /user endpoint when the user has made it
+// public, so private addresses require a separate call.
+func (cfg *config) primaryEmail(ctx context.Context, accessToken string) (string, error) {
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := cfg.get(ctx, userEmailsEndpoint, accessToken, &emails); err != nil {
+		return "", err
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, nil
+		}
+	}
+
+	return "", svcerr.ErrAuthentication
+}
+
+func (cfg *config) get(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return svcerr.ErrAuthentication
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+func (cfg *config) Validate(ctx context.Context, token string) error {
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := cfg.get(ctx, userAPIEndpoint, token, &user); err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return svcerr.ErrAuthentication
+	}
+
+	return nil
+}
+
+func (cfg *config) Refresh(ctx context.Context, token string) (oauth2.Token, error) {
+	return oauth2.Token{}, fmt.Errorf("%s provider does not support token refresh", providerName)
+}
+
+// Revoke revokes token through GitHub's per-app token endpoint, so it can
+// no longer be used. tokenType is ignored: GitHub's revocation endpoint takes
+// only an access token.
+func (cfg *config) Revoke(ctx context.Context, token, tokenType string) error {
+	body, err := json.Marshal(struct {
+		AccessToken string `json:"access_token"`
+	}{AccessToken: token})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://api.github.com/applications/%s/token", cfg.config.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(cfg.config.ClientID, cfg.config.ClientSecret)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return svcerr.ErrAuthentication
+	}
+
+	return nil
+}
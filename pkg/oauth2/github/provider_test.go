@@ -0,0 +1,46 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsEnabled(t *testing.T) {
+	enabled := NewProvider(mgoauth2.Config{ClientID: "id", ClientSecret: "secret"}, "", "")
+	assert.True(t, enabled.IsEnabled())
+
+	disabled := NewProvider(mgoauth2.Config{}, "", "")
+	assert.False(t, disabled.IsEnabled())
+}
+
+func TestName(t *testing.T) {
+	p := NewProvider(mgoauth2.Config{}, "", "")
+	assert.Equal(t, "github", p.Name())
+}
+
+func TestAuthCodeURLRemembersFlow(t *testing.T) {
+	p := NewProvider(mgoauth2.Config{ClientID: "id"}, "", "").(*config)
+
+	authURL, state, err := p.AuthCodeURL(mgoauth2.SignUp)
+	require.NoError(t, err)
+	assert.NotEmpty(t, authURL)
+	assert.NotEmpty(t, state)
+
+	entry, ok := p.states.Take(state)
+	require.True(t, ok)
+	assert.Equal(t, mgoauth2.SignUp, entry.Flow)
+}
+
+func TestUserDetailsRejectsUnknownState(t *testing.T) {
+	p := NewProvider(mgoauth2.Config{ClientID: "id"}, "", "").(*config)
+
+	_, _, _, err := p.UserDetails(context.Background(), "unknown-state", "code")
+	assert.Error(t, err)
+}
\ No newline at end of file
@@ -0,0 +1,76 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package kratos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+type introspectCacheEntry struct {
+	active  bool
+	expires time.Time
+}
+
+// introspectCache caches remote /oauth2/introspect results keyed by a hash
+// of the token, so repeated validation of the same opaque token doesn't hit
+// Kratos/Hydra every time. An entry lives until the earlier of the token's
+// own expiry and maxTTL.
+type introspectCache struct {
+	maxTTL time.Duration
+	lru    *lru.Cache[string, introspectCacheEntry]
+}
+
+func newIntrospectCache(size int, maxTTL time.Duration) (*introspectCache, error) {
+	c, err := lru.New[string, introspectCacheEntry](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &introspectCache{maxTTL: maxTTL, lru: c}, nil
+}
+
+// get reports the cached active state for token and whether it is still
+// fresh. A false found value means the caller must introspect remotely.
+func (c *introspectCache) get(token string) (active, found bool) {
+	key := hashToken(token)
+
+	e, ok := c.lru.Get(key)
+	if !ok {
+		return false, false
+	}
+	if time.Now().After(e.expires) {
+		c.lru.Remove(key)
+
+		return false, false
+	}
+
+	return e.active, true
+}
+
+// put caches active for token. If active is false the entry is still cached
+// (so a revoked/expired token is not re-introspected on every request), but
+// it is dropped immediately once exp, if set, has passed.
+func (c *introspectCache) put(token string, active bool, exp time.Time) {
+	ttl := c.maxTTL
+	if !exp.IsZero() {
+		if until := time.Until(exp); until < ttl {
+			ttl = until
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.lru.Add(hashToken(token), introspectCacheEntry{active: active, expires: time.Now().Add(ttl)})
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
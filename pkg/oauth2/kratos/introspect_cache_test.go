@@ -0,0 +1,79 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package kratos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospectCacheGetMiss(t *testing.T) {
+	c, err := newIntrospectCache(10, time.Minute)
+	require.NoError(t, err)
+
+	_, found := c.get("unknown-token")
+	assert.False(t, found)
+}
+
+func TestIntrospectCachePutGet(t *testing.T) {
+	c, err := newIntrospectCache(10, time.Minute)
+	require.NoError(t, err)
+
+	c.put("token", true, time.Now().Add(time.Hour))
+
+	active, found := c.get("token")
+	assert.True(t, found)
+	assert.True(t, active)
+}
+
+func TestIntrospectCacheHonorsInactiveImmediately(t *testing.T) {
+	c, err := newIntrospectCache(10, time.Minute)
+	require.NoError(t, err)
+
+	c.put("token", false, time.Now().Add(time.Hour))
+
+	active, found := c.get("token")
+	require.True(t, found)
+	assert.False(t, active)
+}
+
+func TestIntrospectCacheTTLIsEarlierOfExpAndMaxTTL(t *testing.T) {
+	// exp is sooner than maxTTL: the entry must not outlive exp.
+	c, err := newIntrospectCache(10, time.Hour)
+	require.NoError(t, err)
+	c.put("token", true, time.Now().Add(10*time.Millisecond))
+
+	time.Sleep(25 * time.Millisecond)
+	_, found := c.get("token")
+	assert.False(t, found)
+}
+
+func TestIntrospectCacheTTLCappedAtMaxTTL(t *testing.T) {
+	// exp is far in the future, so maxTTL caps the cache lifetime instead.
+	c, err := newIntrospectCache(10, 10*time.Millisecond)
+	require.NoError(t, err)
+	c.put("token", true, time.Now().Add(time.Hour))
+
+	time.Sleep(25 * time.Millisecond)
+	_, found := c.get("token")
+	assert.False(t, found)
+}
+
+func TestIntrospectCacheZeroExpUsesMaxTTL(t *testing.T) {
+	c, err := newIntrospectCache(10, time.Minute)
+	require.NoError(t, err)
+	c.put("token", true, time.Time{})
+
+	active, found := c.get("token")
+	assert.True(t, found)
+	assert.True(t, active)
+}
+
+func TestHashTokenIsStableAndDistinct(t *testing.T) {
+	assert.Equal(t, hashToken("a"), hashToken("a"))
+	assert.NotEqual(t, hashToken("a"), hashToken("b"))
+}
@@ -0,0 +1,152 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package kratos
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const jwksEndpoint = "/.well-known/jwks.json"
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (k jsonWebKey) publicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: invalid modulus for key %q: %w", k.Kid, err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("jwks: invalid exponent for key %q: %w", k.Kid, err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+// jwksCache fetches a provider's JSON Web Key Set and keeps it around for
+// refreshInterval before fetching it again, so local JWT validation doesn't
+// need a network round trip on every request.
+type jwksCache struct {
+	baseURL         string
+	refreshInterval time.Duration
+	httpClient      *http.Client
+	onRefreshError  func(error)
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	lastRefresh time.Time
+}
+
+func newJWKSCache(baseURL string, refreshInterval time.Duration) *jwksCache {
+	return &jwksCache{
+		baseURL:         baseURL,
+		refreshInterval: refreshInterval,
+		httpClient:      &http.Client{Timeout: defTimeout},
+		keys:            make(map[string]*rsa.PublicKey),
+	}
+}
+
+// key returns the public key registered for kid, refreshing the cached set
+// first if it is stale or kid is not yet known. It returns an error if kid
+// is still unknown after a refresh.
+func (c *jwksCache) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mu.RLock()
+	k, ok := c.keys[kid]
+	stale := time.Since(c.lastRefresh) > c.refreshInterval
+	c.mu.RUnlock()
+
+	if ok && !stale {
+		return k, nil
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		if ok {
+			// Serve the last known key rather than fail outright on a
+			// transient JWKS fetch error.
+			return k, nil
+		}
+
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if k, ok := c.keys[kid]; ok {
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("jwks: unknown key id %q", kid)
+}
+
+func (c *jwksCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+jwksEndpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.reportRefreshError(err)
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("jwks: unexpected status fetching %s: %d", c.baseURL+jwksEndpoint, resp.StatusCode)
+		c.reportRefreshError(err)
+
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		c.reportRefreshError(err)
+
+		return err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.lastRefresh = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *jwksCache) reportRefreshError(err error) {
+	if c.onRefreshError != nil {
+		c.onRefreshError(err)
+	}
+}
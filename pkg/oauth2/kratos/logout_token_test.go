@@ -0,0 +1,142 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package kratos
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const testClientID = "test-client"
+
+// newTestProvider spins up a JWKS endpoint serving priv's public key under
+// kid, and returns a kratos config pointed at it.
+func newTestProvider(t *testing.T, priv *rsa.PrivateKey, kid string) *config {
+	t.Helper()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := base64.RawURLEncoding.EncodeToString(priv.PublicKey.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(priv.PublicKey.E)).Bytes())
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA","kid":%q,"n":%q,"e":%q}]}`, kid, n, e)
+	}))
+	t.Cleanup(ts.Close)
+
+	return &config{
+		config:  &oauth2.Config{ClientID: testClientID},
+		baseURL: ts.URL,
+		jwks:    newJWKSCache(ts.URL, time.Hour),
+	}
+}
+
+func signLogoutToken(t *testing.T, priv *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	signed, err := tok.SignedString(priv)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validLogoutClaims(baseURL string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss": baseURL,
+		"aud": testClientID,
+		"sub": "user-1",
+		"sid": "session-1",
+		"iat": time.Now().Unix(),
+		"events": map[string]interface{}{
+			backchannelLogoutEvent: map[string]interface{}{},
+		},
+	}
+}
+
+func TestLogoutTokenValid(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := newTestProvider(t, priv, "kid-1")
+
+	token := signLogoutToken(t, priv, "kid-1", validLogoutClaims(cfg.baseURL))
+
+	subject, sid, err := cfg.LogoutToken(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, "user-1", subject)
+	require.Equal(t, "session-1", sid)
+}
+
+func TestLogoutTokenRejectsMissingIat(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := newTestProvider(t, priv, "kid-1")
+
+	claims := validLogoutClaims(cfg.baseURL)
+	delete(claims, "iat")
+	token := signLogoutToken(t, priv, "kid-1", claims)
+
+	_, _, err = cfg.LogoutToken(context.Background(), token)
+	require.Error(t, err)
+}
+
+func TestLogoutTokenRejectsNoncePresent(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := newTestProvider(t, priv, "kid-1")
+
+	claims := validLogoutClaims(cfg.baseURL)
+	claims["nonce"] = "should-not-be-here"
+	token := signLogoutToken(t, priv, "kid-1", claims)
+
+	_, _, err = cfg.LogoutToken(context.Background(), token)
+	require.Error(t, err)
+}
+
+func TestLogoutTokenRejectsWrongEvent(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := newTestProvider(t, priv, "kid-1")
+
+	claims := validLogoutClaims(cfg.baseURL)
+	claims["events"] = map[string]interface{}{"https://example.com/some/other/event": map[string]interface{}{}}
+	token := signLogoutToken(t, priv, "kid-1", claims)
+
+	_, _, err = cfg.LogoutToken(context.Background(), token)
+	require.Error(t, err)
+}
+
+func TestLogoutTokenRejectsWrongIssuer(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := newTestProvider(t, priv, "kid-1")
+
+	claims := validLogoutClaims(cfg.baseURL)
+	claims["iss"] = "https://not-the-expected-issuer.example.com"
+	token := signLogoutToken(t, priv, "kid-1", claims)
+
+	_, _, err = cfg.LogoutToken(context.Background(), token)
+	require.Error(t, err)
+}
+
+func TestLogoutTokenRejectsUnknownKid(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := newTestProvider(t, priv, "kid-1")
+
+	token := signLogoutToken(t, priv, "kid-does-not-exist", validLogoutClaims(cfg.baseURL))
+
+	_, _, err = cfg.LogoutToken(context.Background(), token)
+	require.Error(t, err)
+}
@@ -5,6 +5,7 @@ package kratos
 
 import (
 	"context"
+	"crypto/rsa"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -12,11 +13,13 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	mfclients "github.com/absmach/magistrala/pkg/clients"
 	svcerr "github.com/absmach/magistrala/pkg/errors/service"
 	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/golang-jwt/jwt/v5"
 	ory "github.com/ory/client-go"
 	"golang.org/x/oauth2"
 )
@@ -24,9 +27,19 @@ import (
 const (
 	providerName     = "kratos"
 	defTimeout       = 1 * time.Minute
+	defStateTTL      = 5 * time.Minute
 	userInfoEndpoint = "/userinfo?access_token="
 	authEndpoint     = "/oauth2/auth"
 	TokenEndpoint    = "/oauth2/token"
+	revokeEndpoint   = "/oauth2/revoke"
+
+	defJWKSRefreshInterval = 15 * time.Minute
+	defIntrospectCacheSize = 10_000
+	defIntrospectMaxTTL    = 5 * time.Minute
+
+	// backchannelLogoutEvent is the events claim value the OIDC Back-Channel
+	// Logout 1.0 specification requires on a logout token.
+	backchannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
 )
 
 var scopes = []string{
@@ -40,20 +53,54 @@ var _ mgoauth2.Provider = (*config)(nil)
 type config struct {
 	config        *oauth2.Config
 	client        *ory.APIClient
-	state         string
+	states        mgoauth2.StateStore
 	baseURL       string
 	uiRedirectURL string
 	errorURL      string
+
+	jwks            *jwksCache
+	introspectCache *introspectCache
+
+	cacheHits           atomic.Uint64
+	cacheMisses         atomic.Uint64
+	jwksRefreshFailures atomic.Uint64
+}
+
+// Option configures optional behavior of the Kratos provider returned by
+// NewProvider.
+type Option func(*config)
+
+// WithJWKSRefreshInterval overrides how often the provider refreshes the
+// Kratos/Hydra JWKS used to validate JWT access tokens locally. Defaults to
+// defJWKSRefreshInterval.
+func WithJWKSRefreshInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.jwks = newJWKSCache(c.baseURL, d)
+	}
+}
+
+// WithIntrospectCache overrides the size and max entry lifetime of the LRU
+// cache used for remote introspection results. Defaults to
+// defIntrospectCacheSize entries and defIntrospectMaxTTL.
+func WithIntrospectCache(size int, maxTTL time.Duration) Option {
+	return func(c *config) {
+		cache, err := newIntrospectCache(size, maxTTL)
+		if err == nil {
+			c.introspectCache = cache
+		}
+	}
 }
 
 // NewProvider returns a new Google OAuth provider.
-func NewProvider(cfg mgoauth2.Config, baseURL, uiRedirectURL, errorURL, apiKey string) mgoauth2.Provider {
+func NewProvider(cfg mgoauth2.Config, baseURL, uiRedirectURL, errorURL, apiKey string, opts ...Option) mgoauth2.Provider {
 	conf := ory.NewConfiguration()
 	conf.Servers = []ory.ServerConfiguration{{URL: baseURL}}
 	conf.AddDefaultHeader("Authorization", "Bearer "+apiKey)
 	client := ory.NewAPIClient(conf)
 
-	return &config{
+	introspectCache, _ := newIntrospectCache(defIntrospectCacheSize, defIntrospectMaxTTL)
+
+	c := &config{
 		config: &oauth2.Config{
 			ClientID:     cfg.ClientID,
 			ClientSecret: cfg.ClientSecret,
@@ -64,11 +111,38 @@ func NewProvider(cfg mgoauth2.Config, baseURL, uiRedirectURL, errorURL, apiKey s
 			RedirectURL: cfg.RedirectURL,
 			Scopes:      scopes,
 		},
-		client:        client,
-		baseURL:       baseURL,
-		state:         cfg.State,
-		uiRedirectURL: uiRedirectURL,
-		errorURL:      errorURL,
+		client:          client,
+		baseURL:         baseURL,
+		states:          mgoauth2.NewMemStateStore(defStateTTL),
+		uiRedirectURL:   uiRedirectURL,
+		errorURL:        errorURL,
+		jwks:            newJWKSCache(baseURL, defJWKSRefreshInterval),
+		introspectCache: introspectCache,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.jwks.onRefreshError = func(error) { c.jwksRefreshFailures.Add(1) }
+
+	return c
+}
+
+// Metrics is a snapshot of the provider's local-validation and introspection
+// counters.
+type Metrics struct {
+	CacheHits           uint64
+	CacheMisses         uint64
+	JWKSRefreshFailures uint64
+}
+
+// Metrics returns a snapshot of the introspection cache hit/miss counts and
+// JWKS refresh failure count accumulated so far.
+func (cfg *config) Metrics() Metrics {
+	return Metrics{
+		CacheHits:           cfg.cacheHits.Load(),
+		CacheMisses:         cfg.cacheMisses.Load(),
+		JWKSRefreshFailures: cfg.jwksRefreshFailures.Load(),
 	}
 }
 
@@ -76,8 +150,27 @@ func (cfg *config) Name() string {
 	return providerName
 }
 
-func (cfg *config) State() string {
-	return cfg.state
+// AuthCodeURL generates a PKCE verifier and an opaque state token for flow,
+// remembers their mapping for the lifetime of defStateTTL, and returns the
+// Kratos/Hydra authorization URL carrying the matching S256 code challenge.
+func (cfg *config) AuthCodeURL(flow mgoauth2.Flow) (string, string, error) {
+	verifier, err := mgoauth2.GenerateVerifier()
+	if err != nil {
+		return "", "", err
+	}
+	state, err := mgoauth2.GenerateState()
+	if err != nil {
+		return "", "", err
+	}
+	cfg.states.Put(state, mgoauth2.StateEntry{Flow: flow, Verifier: verifier})
+
+	authURL := cfg.config.AuthCodeURL(
+		state,
+		oauth2.SetAuthURLParam("code_challenge", mgoauth2.ChallengeS256(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	return authURL, state, nil
 }
 
 func (cfg *config) RedirectURL() string {
@@ -92,28 +185,33 @@ func (cfg *config) IsEnabled() bool {
 	return cfg.config.ClientID != "" && cfg.config.ClientSecret != ""
 }
 
-func (cfg *config) UserDetails(ctx context.Context, code string) (mfclients.Client, oauth2.Token, error) {
-	token, err := cfg.config.Exchange(ctx, code)
+func (cfg *config) UserDetails(ctx context.Context, state, code string) (mfclients.Client, oauth2.Token, mgoauth2.Flow, error) {
+	entry, ok := cfg.states.Take(state)
+	if !ok {
+		return mfclients.Client{}, oauth2.Token{}, "", svcerr.ErrAuthentication
+	}
+
+	token, err := cfg.config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", entry.Verifier))
 	if err != nil {
-		return mfclients.Client{}, oauth2.Token{}, err
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
 	}
 	if token.RefreshToken == "" {
-		return mfclients.Client{}, oauth2.Token{}, svcerr.ErrAuthentication
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, svcerr.ErrAuthentication
 	}
 
 	resp, err := http.Get(cfg.baseURL + userInfoEndpoint + url.QueryEscape(token.AccessToken))
 	if err != nil {
-		return mfclients.Client{}, oauth2.Token{}, err
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return mfclients.Client{}, oauth2.Token{}, svcerr.ErrAuthentication
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, svcerr.ErrAuthentication
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return mfclients.Client{}, oauth2.Token{}, err
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
 	}
 
 	var user struct {
@@ -122,11 +220,11 @@ func (cfg *config) UserDetails(ctx context.Context, code string) (mfclients.Clie
 		Email string `json:"email"`
 	}
 	if err := json.Unmarshal(data, &user); err != nil {
-		return mfclients.Client{}, oauth2.Token{}, err
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, err
 	}
 
 	if user.ID == "" || user.Name == "" || user.Email == "" {
-		return mfclients.Client{}, oauth2.Token{}, svcerr.ErrAuthentication
+		return mfclients.Client{}, oauth2.Token{}, entry.Flow, svcerr.ErrAuthentication
 	}
 
 	client := mfclients.Client{
@@ -141,14 +239,75 @@ func (cfg *config) UserDetails(ctx context.Context, code string) (mfclients.Clie
 		Status: mfclients.EnabledStatus,
 	}
 
-	return client, *token, nil
+	return client, *token, entry.Flow, nil
 }
 
+// Validate reports whether token is currently active. JWT access tokens are
+// verified locally against the cached JWKS (signature, exp, iss, aud);
+// opaque tokens, and JWTs whose kid is not in the JWKS, fall back to remote
+// introspection, whose result is cached by token hash until it expires.
 func (cfg *config) Validate(ctx context.Context, token string) error {
+	if kid, ok := jwtKeyID(token); ok {
+		if pub, err := cfg.jwks.key(ctx, kid); err == nil {
+			return cfg.validateLocal(token, pub)
+		}
+	}
+
+	return cfg.validateRemote(ctx, token)
+}
+
+// jwtKeyID reports the kid header of token without verifying its signature,
+// and whether token parses as a JWT at all.
+func jwtKeyID(token string) (string, bool) {
+	parser := jwt.NewParser()
+	tok, _, err := parser.ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return "", false
+	}
+	kid, _ := tok.Header["kid"].(string)
+
+	return kid, kid != ""
+}
+
+func (cfg *config) validateLocal(token string, pub *rsa.PublicKey) error {
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(cfg.baseURL)}
+	if cfg.config.ClientID != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.config.ClientID))
+	}
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(parserOpts...)
+	if _, err := parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return pub, nil
+	}); err != nil {
+		return svcerr.ErrAuthentication
+	}
+
+	return nil
+}
+
+func (cfg *config) validateRemote(ctx context.Context, token string) error {
+	if active, found := cfg.introspectCache.get(token); found {
+		cfg.cacheHits.Add(1)
+		if !active {
+			return svcerr.ErrAuthentication
+		}
+
+		return nil
+	}
+	cfg.cacheMisses.Add(1)
+
 	introspectedToken, resp, err := cfg.client.OAuth2API.IntrospectOAuth2Token(ctx).Token(token).Execute()
 	if err != nil {
 		return decodeError(resp)
 	}
+
+	var exp time.Time
+	if introspectedToken.Exp != nil {
+		exp = time.Unix(*introspectedToken.Exp, 0)
+	}
+	cfg.introspectCache.put(token, introspectedToken.Active, exp)
+
 	if !introspectedToken.Active {
 		return svcerr.ErrAuthentication
 	}
@@ -190,6 +349,86 @@ func (cfg *config) Refresh(ctx context.Context, token string) (oauth2.Token, err
 	return tokenData, nil
 }
 
+// Revoke invalidates token with Hydra's revocation endpoint so it can no
+// longer be used or refreshed.
+func (cfg *config) Revoke(ctx context.Context, token, tokenType string) error {
+	payload := url.Values{"token": {token}}
+	if tokenType != "" {
+		payload.Set("token_type_hint", tokenType)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.baseURL+revokeEndpoint, strings.NewReader(payload.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Basic "+basicAuth(cfg.config.ClientID, cfg.config.ClientSecret))
+
+	client := &http.Client{Timeout: defTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return svcerr.ErrAuthentication
+	}
+
+	return nil
+}
+
+// LogoutToken validates logoutJWT as an OIDC Back-Channel Logout 1.0 logout
+// token - verifying its signature against the cached JWKS and checking
+// iss/aud/iat, that events carries the back-channel-logout event, and that
+// no nonce claim is present - and returns the subject and session id it
+// identifies.
+func (cfg *config) LogoutToken(ctx context.Context, logoutJWT string) (subject, sid string, err error) {
+	kid, ok := jwtKeyID(logoutJWT)
+	if !ok {
+		return "", "", svcerr.ErrAuthentication
+	}
+	pub, err := cfg.jwks.key(ctx, kid)
+	if err != nil {
+		return "", "", svcerr.ErrAuthentication
+	}
+
+	parserOpts := []jwt.ParserOption{jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(cfg.baseURL)}
+	if cfg.config.ClientID != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.config.ClientID))
+	}
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(parserOpts...)
+	if _, err := parser.ParseWithClaims(logoutJWT, claims, func(*jwt.Token) (interface{}, error) {
+		return pub, nil
+	}); err != nil {
+		return "", "", svcerr.ErrAuthentication
+	}
+
+	if _, hasNonce := claims["nonce"]; hasNonce {
+		return "", "", svcerr.ErrAuthentication
+	}
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil || iat.After(time.Now()) {
+		return "", "", svcerr.ErrAuthentication
+	}
+
+	events, _ := claims["events"].(map[string]interface{})
+	if _, ok := events[backchannelLogoutEvent]; !ok {
+		return "", "", svcerr.ErrAuthentication
+	}
+
+	subject, err = claims.GetSubject()
+	if err != nil || subject == "" {
+		return "", "", svcerr.ErrAuthentication
+	}
+	sid, _ = claims["sid"].(string)
+
+	return subject, sid, nil
+}
+
 func basicAuth(id, secret string) string {
 	auth := id + ":" + secret
 	return base64.StdEncoding.EncodeToString([]byte(auth))
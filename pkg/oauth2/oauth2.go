@@ -0,0 +1,93 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"errors"
+
+	mfclients "github.com/absmach/magistrala/pkg/clients"
+	"golang.org/x/oauth2"
+)
+
+// ErrUnknownProvider indicates that the requested provider name has not been
+// registered in the Registry.
+var ErrUnknownProvider = errors.New("unknown oauth2 provider")
+
+// Provider represents an interface to communicate with different OAuth2 providers.
+type Provider interface {
+	// Name returns provider name.
+	Name() string
+
+	// AuthCodeURL builds the URL the user is redirected to in order to start
+	// flow (sign-in or sign-up), along with the opaque state token embedded
+	// in it. The provider is responsible for remembering whatever it needs
+	// (PKCE verifier, flow kind, ...) to recover them from that same state
+	// once the user is redirected back.
+	AuthCodeURL(flow Flow) (authURL, state string, err error)
+
+	// RedirectURL returns the redirect (UI) URL.
+	RedirectURL() string
+
+	// ErrorURL returns the error URL.
+	ErrorURL() string
+
+	// IsEnabled checks if the provider is enabled.
+	IsEnabled() bool
+
+	// UserDetails exchanges the code for the user details. state is the
+	// value returned by the AuthCodeURL call that started the flow; it is
+	// used to recover the flow kind (and, where applicable, the PKCE
+	// verifier) the exchange needs.
+	UserDetails(ctx context.Context, state, code string) (mfclients.Client, oauth2.Token, Flow, error)
+
+	// Validate validates the token.
+	Validate(ctx context.Context, token string) error
+
+	// Refresh refreshes the token and returns a new access token.
+	Refresh(ctx context.Context, token string) (oauth2.Token, error)
+
+	// Revoke invalidates token (an access or refresh token) with the
+	// provider. tokenType is a token_type_hint such as "access_token" or
+	// "refresh_token"; providers that don't need the hint may ignore it.
+	Revoke(ctx context.Context, token, tokenType string) error
+}
+
+// Config is the configuration for an OAuth2 provider client.
+type Config struct {
+	ClientID     string `env:"CLIENT_ID"     envDefault:""`
+	ClientSecret string `env:"CLIENT_SECRET" envDefault:""`
+	RedirectURL  string `env:"REDIRECT_URL"  envDefault:""`
+}
+
+// Registry holds the set of OAuth2 providers enabled for the running service,
+// keyed by the provider Name so HTTP handlers can look one up from the
+// `{provider}` path parameter.
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry builds a Registry out of the given providers. Providers are
+// keyed by their Name(), so registering two providers with the same name
+// overwrites the first with the second.
+func NewRegistry(providers ...Provider) Registry {
+	reg := Registry{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		reg.providers[p.Name()] = p
+	}
+
+	return reg
+}
+
+// Get returns the provider registered under name and whether it was found.
+func (r Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+
+	return p, ok
+}
+
+// IsEmpty returns true if the registry has no registered providers.
+func (r Registry) IsEmpty() bool {
+	return len(r.providers) == 0
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"context"
+	"testing"
+
+	mfclients "github.com/absmach/magistrala/pkg/clients"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+type stubProvider struct {
+	name string
+	// id lets tests tell apart two providers registered under the same
+	// name, which a plain name-only struct could not distinguish.
+	id int
+}
+
+func (p stubProvider) Name() string { return p.name }
+
+func (p stubProvider) AuthCodeURL(flow Flow) (string, string, error) { return "", "", nil }
+
+func (p stubProvider) RedirectURL() string { return "" }
+
+func (p stubProvider) ErrorURL() string { return "" }
+
+func (p stubProvider) IsEnabled() bool { return true }
+
+func (p stubProvider) UserDetails(ctx context.Context, state, code string) (mfclients.Client, oauth2.Token, Flow, error) {
+	return mfclients.Client{}, oauth2.Token{}, "", nil
+}
+
+func (p stubProvider) Validate(ctx context.Context, token string) error { return nil }
+
+func (p stubProvider) Refresh(ctx context.Context, token string) (oauth2.Token, error) {
+	return oauth2.Token{}, nil
+}
+
+func (p stubProvider) Revoke(ctx context.Context, token, tokenType string) error { return nil }
+
+func TestNewRegistryGet(t *testing.T) {
+	kratos := stubProvider{name: "kratos"}
+	github := stubProvider{name: "github"}
+	reg := NewRegistry(kratos, github)
+
+	got, ok := reg.Get("kratos")
+	assert.True(t, ok)
+	assert.Equal(t, kratos, got)
+
+	got, ok = reg.Get("github")
+	assert.True(t, ok)
+	assert.Equal(t, github, got)
+}
+
+func TestRegistryGetUnknownProvider(t *testing.T) {
+	reg := NewRegistry(stubProvider{name: "kratos"})
+
+	_, ok := reg.Get("does-not-exist")
+	assert.False(t, ok)
+}
+
+func TestRegistryDuplicateNameLastWriteWins(t *testing.T) {
+	first := stubProvider{name: "kratos", id: 1}
+	second := stubProvider{name: "kratos", id: 2}
+	reg := NewRegistry(first, second)
+
+	got, ok := reg.Get("kratos")
+	assert.True(t, ok)
+	assert.Equal(t, second, got)
+}
+
+func TestRegistryIsEmpty(t *testing.T) {
+	assert.True(t, NewRegistry().IsEmpty())
+	assert.False(t, NewRegistry(stubProvider{name: "kratos"}).IsEmpty())
+}
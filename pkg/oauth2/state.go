@@ -0,0 +1,136 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// Flow identifies whether an authorization flow was started to sign an
+// existing user in or to register a new one, so UserDetails can tell the two
+// apart once the provider redirects back.
+type Flow string
+
+const (
+	// SignIn is used for a flow that looks up an existing user.
+	SignIn Flow = "signin"
+	// SignUp is used for a flow that registers a new user.
+	SignUp Flow = "signup"
+)
+
+// verifierBytes is the number of random bytes used to generate a PKCE code
+// verifier. 32 bytes base64url-encode to 43 characters, the minimum length
+// allowed by RFC 7636.
+const verifierBytes = 32
+
+// stateBytes is the number of random bytes used to generate an opaque state
+// token.
+const stateBytes = 24
+
+// StateEntry is what a StateStore keeps against the opaque state token
+// handed to the identity provider: the flow the request was started for and,
+// for providers that use PKCE, the code verifier generated for it.
+type StateEntry struct {
+	Flow     Flow
+	Verifier string
+}
+
+// StateStore persists state -> StateEntry mappings between the moment a
+// provider builds an authorization URL and the moment it handles the
+// resulting callback. Implementations must expire entries so a state token
+// cannot be replayed indefinitely.
+type StateStore interface {
+	// Put stores entry under state.
+	Put(state string, entry StateEntry)
+
+	// Take retrieves and removes the entry stored under state. It returns
+	// false if state is unknown or has already expired.
+	Take(state string) (StateEntry, bool)
+}
+
+// MemStateStore is an in-memory StateStore whose entries expire after a
+// fixed TTL. It is the default StateStore used by providers; deployments
+// that run more than one replica of a service should back it with something
+// shared (e.g. Redis) behind the same interface instead.
+type MemStateStore struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]memStateEntry
+}
+
+type memStateEntry struct {
+	entry   StateEntry
+	expires time.Time
+}
+
+// NewMemStateStore returns a MemStateStore whose entries expire ttl after
+// being put.
+func NewMemStateStore(ttl time.Duration) *MemStateStore {
+	return &MemStateStore{
+		ttl:     ttl,
+		entries: make(map[string]memStateEntry),
+	}
+}
+
+func (s *MemStateStore) Put(state string, entry StateEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gc()
+	s.entries[state] = memStateEntry{entry: entry, expires: time.Now().Add(s.ttl)}
+}
+
+func (s *MemStateStore) Take(state string) (StateEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[state]
+	delete(s.entries, state)
+	if !ok || time.Now().After(e.expires) {
+		return StateEntry{}, false
+	}
+
+	return e.entry, true
+}
+
+// gc drops expired entries. Callers must hold s.mu.
+func (s *MemStateStore) gc() {
+	now := time.Now()
+	for k, v := range s.entries {
+		if now.After(v.expires) {
+			delete(s.entries, k)
+		}
+	}
+}
+
+// GenerateState returns a random, URL-safe opaque state token.
+func GenerateState() (string, error) {
+	buf := make([]byte, stateBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateVerifier returns a random PKCE code verifier per RFC 7636.
+func GenerateVerifier() (string, error) {
+	buf := make([]byte, verifierBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// ChallengeS256 derives the S256 PKCE code challenge for verifier.
+func ChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
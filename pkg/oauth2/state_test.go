@@ -0,0 +1,87 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package oauth2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemStateStorePutTake(t *testing.T) {
+	store := NewMemStateStore(time.Minute)
+	entry := StateEntry{Flow: SignUp, Verifier: "verifier"}
+	store.Put("state-1", entry)
+
+	got, ok := store.Take("state-1")
+	assert.True(t, ok)
+	assert.Equal(t, entry, got)
+}
+
+func TestMemStateStoreTakeIsSingleUse(t *testing.T) {
+	store := NewMemStateStore(time.Minute)
+	store.Put("state-1", StateEntry{Flow: SignIn})
+
+	_, ok := store.Take("state-1")
+	require.True(t, ok)
+
+	// A replayed state must not be honored a second time.
+	_, ok = store.Take("state-1")
+	assert.False(t, ok)
+}
+
+func TestMemStateStoreUnknownState(t *testing.T) {
+	store := NewMemStateStore(time.Minute)
+
+	_, ok := store.Take("never-put")
+	assert.False(t, ok)
+}
+
+func TestMemStateStoreExpiry(t *testing.T) {
+	store := NewMemStateStore(10 * time.Millisecond)
+	store.Put("state-1", StateEntry{Flow: SignIn})
+
+	time.Sleep(25 * time.Millisecond)
+
+	_, ok := store.Take("state-1")
+	assert.False(t, ok)
+}
+
+func TestGenerateStateIsRandom(t *testing.T) {
+	a, err := GenerateState()
+	require.NoError(t, err)
+	b, err := GenerateState()
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, a)
+	assert.NotEqual(t, a, b)
+}
+
+func TestGenerateVerifierIsRandom(t *testing.T) {
+	a, err := GenerateVerifier()
+	require.NoError(t, err)
+	b, err := GenerateVerifier()
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, a)
+	assert.NotEqual(t, a, b)
+}
+
+func TestChallengeS256(t *testing.T) {
+	// RFC 7636 Appendix B worked example.
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.Equal(t, want, ChallengeS256(verifier))
+}
+
+func TestChallengeS256IsDeterministicPerVerifier(t *testing.T) {
+	verifier, err := GenerateVerifier()
+	require.NoError(t, err)
+
+	assert.Equal(t, ChallengeS256(verifier), ChallengeS256(verifier))
+	assert.NotEqual(t, ChallengeS256(verifier), ChallengeS256(verifier+"x"))
+}
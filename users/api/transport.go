@@ -0,0 +1,170 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/absmach/magistrala/pkg/groups"
+	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/absmach/magistrala/users"
+	"github.com/go-chi/chi/v5"
+)
+
+// MakeHandler returns an HTTP handler for the users and groups services. In
+// addition to the existing client/group routes (registered elsewhere on
+// mux), it mounts, for every provider in providers, an
+// `/oauth/{provider}/authorize` and `/oauth/{provider}/callback` pair, plus
+// a single `/oauth/backchannel-logout` endpoint that validates logout tokens
+// with the provider registered as "kratos".
+func MakeHandler(csvc users.Service, gsvc groups.Service, mux *chi.Mux, logger *slog.Logger, instanceID string, passRegex *regexp.Regexp, providers mgoauth2.Registry) http.Handler {
+	mountOAuthRoutes(mux, csvc, providers, logger)
+
+	return mux
+}
+
+// mountOAuthRoutes wires the OAuth2 routes on mux. The authorize and
+// callback routes look the requested provider up in the registry by the
+// `{provider}` path parameter and serve it only if it is registered and
+// IsEnabled reports true - no route changes required. The back-channel
+// logout route always uses the provider registered as "kratos".
+func mountOAuthRoutes(mux *chi.Mux, svc users.Service, providers mgoauth2.Registry, logger *slog.Logger) {
+	mux.Route("/oauth/{provider}", func(r chi.Router) {
+		r.Get("/authorize", oauthAuthorizeEndpoint(providers, logger))
+		r.Get("/callback", oauthCallbackEndpoint(providers, svc, logger))
+	})
+	mux.Post("/oauth/backchannel-logout", backchannelLogoutEndpoint(providers, svc, logger))
+}
+
+// oauthAuthorizeEndpoint redirects the caller to the requested provider's
+// authorization URL, starting a sign-up flow when `?flow=signup` is given
+// and a sign-in flow otherwise.
+func oauthAuthorizeEndpoint(providers mgoauth2.Registry, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provider, ok := providers.Get(chi.URLParam(r, "provider"))
+		if !ok || !provider.IsEnabled() {
+			http.Error(w, mgoauth2.ErrUnknownProvider.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		flow := mgoauth2.SignIn
+		if r.URL.Query().Get("flow") == string(mgoauth2.SignUp) {
+			flow = mgoauth2.SignUp
+		}
+
+		authURL, _, err := provider.AuthCodeURL(flow)
+		if err != nil {
+			logger.Error(fmt.Sprintf("failed to build %s authorization URL: %s", provider.Name(), err))
+			http.Redirect(w, r, provider.ErrorURL(), http.StatusSeeOther)
+
+			return
+		}
+
+		http.Redirect(w, r, authURL, http.StatusSeeOther)
+	}
+}
+
+// oauthCallbackEndpoint completes the authorization code exchange with the
+// requested provider and hands the resulting identity, together with the
+// flow it was started for, to users.Service.OAuthCallback so it creates a
+// new account on sign-up or looks an existing one up on sign-in, instead of
+// treating every unrecognized identity as an authentication failure.
+func oauthCallbackEndpoint(providers mgoauth2.Registry, svc users.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provider, ok := providers.Get(chi.URLParam(r, "provider"))
+		if !ok || !provider.IsEnabled() {
+			http.Error(w, mgoauth2.ErrUnknownProvider.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		state := r.URL.Query().Get("state")
+		code := r.URL.Query().Get("code")
+
+		client, _, flow, err := provider.UserDetails(r.Context(), state, code)
+		if err != nil {
+			logger.Error(fmt.Sprintf("%s oauth callback failed: %s", provider.Name(), err))
+			http.Redirect(w, r, provider.ErrorURL(), http.StatusSeeOther)
+
+			return
+		}
+
+		client, err = svc.OAuthCallback(r.Context(), client, flow)
+		if err != nil {
+			logger.Error(fmt.Sprintf("%s oauth %s failed: %s", provider.Name(), flow, err))
+			http.Redirect(w, r, provider.ErrorURL(), http.StatusSeeOther)
+
+			return
+		}
+
+		if _, err := svc.IssueToken(r.Context(), client.Credentials.Identity, "", ""); err != nil {
+			logger.Error(fmt.Sprintf("failed to issue token after %s oauth callback: %s", provider.Name(), err))
+			http.Redirect(w, r, provider.ErrorURL(), http.StatusSeeOther)
+
+			return
+		}
+
+		http.Redirect(w, r, provider.RedirectURL(), http.StatusSeeOther)
+	}
+}
+
+// logoutTokenValidator is implemented by providers that support OIDC
+// back-channel logout (currently only kratos - see
+// pkg/oauth2/kratos.(*config).LogoutToken).
+type logoutTokenValidator interface {
+	LogoutToken(ctx context.Context, logoutJWT string) (subject, sid string, err error)
+}
+
+// backchannelLogoutEndpoint validates an OIDC back-channel logout token
+// posted by the identity provider and, on success, invalidates every
+// session belonging to the subject it identifies through users.Service.
+func backchannelLogoutEndpoint(providers mgoauth2.Registry, svc users.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+		logoutJWT := r.PostForm.Get("logout_token")
+		if logoutJWT == "" {
+			http.Error(w, "missing logout_token", http.StatusBadRequest)
+
+			return
+		}
+
+		provider, ok := providers.Get("kratos")
+		if !ok {
+			http.Error(w, mgoauth2.ErrUnknownProvider.Error(), http.StatusNotFound)
+
+			return
+		}
+		validator, ok := provider.(logoutTokenValidator)
+		if !ok {
+			http.Error(w, fmt.Sprintf("%s provider does not support back-channel logout", provider.Name()), http.StatusNotImplemented)
+
+			return
+		}
+
+		subject, _, err := validator.LogoutToken(r.Context(), logoutJWT)
+		if err != nil {
+			http.Error(w, "invalid logout token", http.StatusBadRequest)
+
+			return
+		}
+
+		if err := svc.InvalidateSessions(r.Context(), subject); err != nil {
+			logger.Error(fmt.Sprintf("failed to invalidate sessions for subject %s: %s", subject, err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/absmach/magistrala"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+const (
+	testRedirectURL = "https://ui.example.com/redirect"
+	testErrorURL    = "https://ui.example.com/error"
+)
+
+type mockProvider struct {
+	name        string
+	client      mgclients.Client
+	flow        mgoauth2.Flow
+	userDetails error
+}
+
+func (p *mockProvider) Name() string { return p.name }
+
+func (p *mockProvider) AuthCodeURL(flow mgoauth2.Flow) (string, string, error) {
+	return "https://provider.example.com/authorize", "state", nil
+}
+
+func (p *mockProvider) RedirectURL() string { return testRedirectURL }
+
+func (p *mockProvider) ErrorURL() string { return testErrorURL }
+
+func (p *mockProvider) IsEnabled() bool { return true }
+
+func (p *mockProvider) UserDetails(ctx context.Context, state, code string) (mgclients.Client, oauth2.Token, mgoauth2.Flow, error) {
+	return p.client, oauth2.Token{}, p.flow, p.userDetails
+}
+
+func (p *mockProvider) Validate(ctx context.Context, token string) error { return nil }
+
+func (p *mockProvider) Refresh(ctx context.Context, token string) (oauth2.Token, error) {
+	return oauth2.Token{}, nil
+}
+
+func (p *mockProvider) Revoke(ctx context.Context, token, tokenType string) error { return nil }
+
+// mockLogoutProvider additionally implements logoutTokenValidator, the way
+// the kratos provider does, so backchannelLogoutEndpoint can be exercised
+// without depending on the kratos package.
+type mockLogoutProvider struct {
+	mockProvider
+	subject, sid string
+	logoutErr    error
+}
+
+func (p *mockLogoutProvider) LogoutToken(ctx context.Context, logoutJWT string) (string, string, error) {
+	return p.subject, p.sid, p.logoutErr
+}
+
+type mockUserService struct {
+	oauthCallbackErr      error
+	issueTokenErr         error
+	invalidateSubject     string
+	invalidateSessionsErr error
+}
+
+func (s *mockUserService) IssueToken(ctx context.Context, identity, secret, domainID string) (*magistrala.Token, error) {
+	return &magistrala.Token{AccessToken: "token"}, s.issueTokenErr
+}
+
+func (s *mockUserService) OAuthCallback(ctx context.Context, client mgclients.Client, flow mgoauth2.Flow) (mgclients.Client, error) {
+	return client, s.oauthCallbackErr
+}
+
+func (s *mockUserService) InvalidateSessions(ctx context.Context, subject string) error {
+	s.invalidateSubject = subject
+
+	return s.invalidateSessionsErr
+}
+
+func newTestMux(providers mgoauth2.Registry, svc *mockUserService) *chi.Mux {
+	mux := chi.NewRouter()
+	mountOAuthRoutes(mux, svc, providers, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	return mux
+}
+
+func TestOauthCallbackEndpointSignUpRedirectsOnSuccess(t *testing.T) {
+	provider := &mockProvider{
+		name: "kratos",
+		client: mgclients.Client{
+			Credentials: mgclients.Credentials{Identity: "new@example.com"},
+		},
+		flow: mgoauth2.SignUp,
+	}
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(provider), svc)
+
+	req := httptest.NewRequest("GET", "/oauth/kratos/callback?state=s&code=c", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, 303, rec.Code)
+	assert.Equal(t, testRedirectURL, rec.Header().Get("Location"))
+}
+
+func TestOauthCallbackEndpointRedirectsToErrorURLOnServiceFailure(t *testing.T) {
+	provider := &mockProvider{name: "kratos", flow: mgoauth2.SignIn}
+	svc := &mockUserService{oauthCallbackErr: errors.New("unknown identity")}
+	mux := newTestMux(mgoauth2.NewRegistry(provider), svc)
+
+	req := httptest.NewRequest("GET", "/oauth/kratos/callback?state=s&code=c", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, 303, rec.Code)
+	assert.Equal(t, testErrorURL, rec.Header().Get("Location"))
+}
+
+func TestOauthCallbackEndpointUnknownProviderReturns404(t *testing.T) {
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(), svc)
+
+	req := httptest.NewRequest("GET", "/oauth/kratos/callback?state=s&code=c", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, 404, rec.Code)
+}
+
+func backchannelLogoutRequest() *http.Request {
+	body := url.Values{"logout_token": {"any-token"}}
+	req := httptest.NewRequest("POST", "/oauth/backchannel-logout", strings.NewReader(body.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req
+}
+
+func TestBackchannelLogoutEndpointInvalidatesSessionsOnSuccess(t *testing.T) {
+	provider := &mockLogoutProvider{
+		mockProvider: mockProvider{name: "kratos"},
+		subject:      "user-1",
+		sid:          "session-1",
+	}
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(provider), svc)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, backchannelLogoutRequest())
+
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, "user-1", svc.invalidateSubject)
+}
+
+func TestBackchannelLogoutEndpointRejectsInvalidLogoutToken(t *testing.T) {
+	provider := &mockLogoutProvider{
+		mockProvider: mockProvider{name: "kratos"},
+		logoutErr:    errors.New("invalid signature"),
+	}
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(provider), svc)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, backchannelLogoutRequest())
+
+	assert.Equal(t, 400, rec.Code)
+	assert.Empty(t, svc.invalidateSubject)
+}
+
+func TestBackchannelLogoutEndpointMissingLogoutTokenReturns400(t *testing.T) {
+	provider := &mockLogoutProvider{mockProvider: mockProvider{name: "kratos"}}
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(provider), svc)
+
+	req := httptest.NewRequest("POST", "/oauth/backchannel-logout", strings.NewReader(""))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, 400, rec.Code)
+}
+
+func TestBackchannelLogoutEndpointProviderWithoutLogoutSupportNotImplemented(t *testing.T) {
+	provider := &mockProvider{name: "kratos"}
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(provider), svc)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, backchannelLogoutRequest())
+
+	assert.Equal(t, 501, rec.Code)
+}
+
+func TestBackchannelLogoutEndpointNoKratosProviderReturns404(t *testing.T) {
+	svc := &mockUserService{}
+	mux := newTestMux(mgoauth2.NewRegistry(), svc)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, backchannelLogoutRequest())
+
+	assert.Equal(t, 404, rec.Code)
+}
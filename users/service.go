@@ -0,0 +1,101 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+// Package users provides the user account service: client life-cycle,
+// authentication, and OAuth2 account linking.
+package users
+
+import (
+	"context"
+
+	"github.com/absmach/magistrala"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+)
+
+// Service defines the user account operations consumed by the users API.
+type Service interface {
+	// IssueToken issues an access/refresh token pair for the client
+	// identified by identity. secret is required for password
+	// authentication; callers that already established the client's
+	// identity by another means (e.g. right after a successful OAuth2
+	// callback) pass it empty.
+	IssueToken(ctx context.Context, identity, secret, domainID string) (*magistrala.Token, error)
+
+	// OAuthCallback resolves the client identity returned by an OAuth2
+	// provider against the client store. On flow == oauth2.SignUp it
+	// creates the client if none is registered under that identity yet,
+	// returning the existing one otherwise. On flow == oauth2.SignIn it
+	// looks the client up and fails with svcerr.ErrNotFound if no matching
+	// identity is registered, instead of silently treating an unknown
+	// identity as unauthenticated.
+	OAuthCallback(ctx context.Context, client mgclients.Client, flow mgoauth2.Flow) (mgclients.Client, error)
+
+	// InvalidateSessions revokes every active session belonging to
+	// subject, e.g. in response to an OIDC back-channel logout
+	// notification.
+	InvalidateSessions(ctx context.Context, subject string) error
+}
+
+// Repository persists client accounts.
+type Repository interface {
+	// Save persists a new client and returns it with any store-assigned
+	// fields (e.g. ID) populated.
+	Save(ctx context.Context, client mgclients.Client) (mgclients.Client, error)
+
+	// RetrieveByIdentity returns the client registered under identity, or
+	// svcerr.ErrNotFound if none is.
+	RetrieveByIdentity(ctx context.Context, identity string) (mgclients.Client, error)
+}
+
+// TokenIssuer mints access/refresh tokens for an authenticated client. It is
+// the seam service uses for IssueToken, kept narrow so tests can supply a
+// stub instead of the real auth stack.
+type TokenIssuer interface {
+	Issue(ctx context.Context, identity, secret, domainID string) (*magistrala.Token, error)
+}
+
+// SessionStore tracks the sessions issued per subject so they can be revoked
+// in bulk, e.g. on back-channel logout.
+type SessionStore interface {
+	// Add records sessionID as active for subject.
+	Add(subject, sessionID string)
+
+	// InvalidateAll revokes every session recorded for subject.
+	InvalidateAll(subject string)
+}
+
+type service struct {
+	repo     Repository
+	issuer   TokenIssuer
+	sessions SessionStore
+}
+
+// NewService returns a new users Service backed by repo, issuer, and
+// sessions.
+func NewService(repo Repository, issuer TokenIssuer, sessions SessionStore) Service {
+	return &service{repo: repo, issuer: issuer, sessions: sessions}
+}
+
+func (svc *service) IssueToken(ctx context.Context, identity, secret, domainID string) (*magistrala.Token, error) {
+	return svc.issuer.Issue(ctx, identity, secret, domainID)
+}
+
+func (svc *service) OAuthCallback(ctx context.Context, client mgclients.Client, flow mgoauth2.Flow) (mgclients.Client, error) {
+	existing, err := svc.repo.RetrieveByIdentity(ctx, client.Credentials.Identity)
+	switch {
+	case err == nil:
+		return existing, nil
+	case flow == mgoauth2.SignUp:
+		return svc.repo.Save(ctx, client)
+	default:
+		return mgclients.Client{}, svcerr.ErrNotFound
+	}
+}
+
+func (svc *service) InvalidateSessions(ctx context.Context, subject string) error {
+	svc.sessions.InvalidateAll(subject)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/magistrala"
+	mgclients "github.com/absmach/magistrala/pkg/clients"
+	svcerr "github.com/absmach/magistrala/pkg/errors/service"
+	mgoauth2 "github.com/absmach/magistrala/pkg/oauth2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRepository struct {
+	byIdentity map[string]mgclients.Client
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{byIdentity: make(map[string]mgclients.Client)}
+}
+
+func (r *mockRepository) Save(ctx context.Context, client mgclients.Client) (mgclients.Client, error) {
+	r.byIdentity[client.Credentials.Identity] = client
+
+	return client, nil
+}
+
+func (r *mockRepository) RetrieveByIdentity(ctx context.Context, identity string) (mgclients.Client, error) {
+	client, ok := r.byIdentity[identity]
+	if !ok {
+		return mgclients.Client{}, svcerr.ErrNotFound
+	}
+
+	return client, nil
+}
+
+type mockIssuer struct{}
+
+func (mockIssuer) Issue(ctx context.Context, identity, secret, domainID string) (*magistrala.Token, error) {
+	return &magistrala.Token{AccessToken: "token-for-" + identity}, nil
+}
+
+type mockSessionStore struct {
+	invalidated []string
+}
+
+func (s *mockSessionStore) Add(subject, sessionID string) {}
+
+func (s *mockSessionStore) InvalidateAll(subject string) {
+	s.invalidated = append(s.invalidated, subject)
+}
+
+func TestOAuthCallbackSignUpCreatesUnknownIdentity(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, mockIssuer{}, &mockSessionStore{})
+
+	client := mgclients.Client{ID: "1", Credentials: mgclients.Credentials{Identity: "new@example.com"}}
+
+	got, err := svc.OAuthCallback(context.Background(), client, mgoauth2.SignUp)
+	require.NoError(t, err)
+	assert.Equal(t, client, got)
+
+	stored, err := repo.RetrieveByIdentity(context.Background(), "new@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, client, stored)
+}
+
+func TestOAuthCallbackSignUpReturnsExistingIdentity(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, mockIssuer{}, &mockSessionStore{})
+	existing := mgclients.Client{ID: "1", Credentials: mgclients.Credentials{Identity: "known@example.com"}}
+	repo.byIdentity[existing.Credentials.Identity] = existing
+
+	got, err := svc.OAuthCallback(context.Background(), mgclients.Client{Credentials: mgclients.Credentials{Identity: "known@example.com"}}, mgoauth2.SignUp)
+	require.NoError(t, err)
+	assert.Equal(t, existing, got)
+}
+
+func TestOAuthCallbackSignInReturnsExistingIdentity(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, mockIssuer{}, &mockSessionStore{})
+	existing := mgclients.Client{ID: "1", Credentials: mgclients.Credentials{Identity: "known@example.com"}}
+	repo.byIdentity[existing.Credentials.Identity] = existing
+
+	got, err := svc.OAuthCallback(context.Background(), mgclients.Client{Credentials: mgclients.Credentials{Identity: "known@example.com"}}, mgoauth2.SignIn)
+	require.NoError(t, err)
+	assert.Equal(t, existing, got)
+}
+
+func TestOAuthCallbackSignInRejectsUnknownIdentity(t *testing.T) {
+	repo := newMockRepository()
+	svc := NewService(repo, mockIssuer{}, &mockSessionStore{})
+
+	_, err := svc.OAuthCallback(context.Background(), mgclients.Client{Credentials: mgclients.Credentials{Identity: "unknown@example.com"}}, mgoauth2.SignIn)
+	assert.ErrorIs(t, err, svcerr.ErrNotFound)
+}
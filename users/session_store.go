@@ -0,0 +1,46 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import "sync"
+
+// MemSessionStore is an in-memory SessionStore keyed by subject. It is safe
+// for concurrent use; deployments running more than one replica of the
+// service should back it with something shared instead.
+type MemSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]struct{}
+}
+
+// NewMemSessionStore returns an empty MemSessionStore.
+func NewMemSessionStore() *MemSessionStore {
+	return &MemSessionStore{sessions: make(map[string]map[string]struct{})}
+}
+
+func (s *MemSessionStore) Add(subject, sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sessions[subject] == nil {
+		s.sessions[subject] = make(map[string]struct{})
+	}
+	s.sessions[subject][sessionID] = struct{}{}
+}
+
+func (s *MemSessionStore) InvalidateAll(subject string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, subject)
+}
+
+// Active reports whether sessionID is still recorded as active for subject.
+func (s *MemSessionStore) Active(subject, sessionID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.sessions[subject][sessionID]
+
+	return ok
+}
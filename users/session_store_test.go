@@ -0,0 +1,40 @@
+// Copyright (c) Abstract Machines
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemSessionStoreAddAndActive(t *testing.T) {
+	store := NewMemSessionStore()
+	store.Add("user-1", "session-1")
+
+	assert.True(t, store.Active("user-1", "session-1"))
+	assert.False(t, store.Active("user-1", "session-2"))
+	assert.False(t, store.Active("user-2", "session-1"))
+}
+
+func TestMemSessionStoreInvalidateAllRevokesEverySession(t *testing.T) {
+	store := NewMemSessionStore()
+	store.Add("user-1", "session-1")
+	store.Add("user-1", "session-2")
+	store.Add("user-2", "session-3")
+
+	store.InvalidateAll("user-1")
+
+	assert.False(t, store.Active("user-1", "session-1"))
+	assert.False(t, store.Active("user-1", "session-2"))
+	assert.True(t, store.Active("user-2", "session-3"))
+}
+
+func TestMemSessionStoreInvalidateAllUnknownSubjectIsNoop(t *testing.T) {
+	store := NewMemSessionStore()
+
+	assert.NotPanics(t, func() {
+		store.InvalidateAll("never-added")
+	})
+}